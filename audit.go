@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditLogMaxBytes is the size at which a JSONL audit log is rolled to
+// a timestamped sibling file.
+const auditLogMaxBytes = 10 * 1024 * 1024
+
+// AuditEntry records a single restart attempt for compliance and
+// debugging.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Job      string    `json:"job"`
+	User     string    `json:"user"`
+	Result   string    `json:"result"`
+	Duration string    `json:"duration"`
+}
+
+// AuditLog appends AuditEntry records to a rolling JSONL file and lets
+// callers replay a job's history.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends entry to the log, rolling the file first if it has
+// grown past auditLogMaxBytes.
+func (a *AuditLog) Record(entry AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.rollIfNeeded(); err != nil {
+		logger.Warn("Failed to roll audit log", "path", a.path, "error", err)
+	}
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log %s: %w", a.path, err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(entry); err != nil {
+		return fmt.Errorf("writing audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AuditLog) rollIfNeeded() error {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return nil
+	}
+	if info.Size() < auditLogMaxBytes {
+		return nil
+	}
+
+	rolled := fmt.Sprintf("%s.%s", a.path, time.Now().UTC().Format("20060102T150405"))
+	return os.Rename(a.path, rolled)
+}
+
+// History returns the most recent entries recorded for job, oldest
+// first, capped at limit (0 means unlimited).
+func (a *AuditLog) History(job string, limit int) ([]AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.Open(a.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening audit log %s: %w", a.path, err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	dec := json.NewDecoder(f)
+	for {
+		var entry AuditEntry
+		if err := dec.Decode(&entry); err != nil {
+			break
+		}
+		if entry.Job == job {
+			entries = append(entries, entry)
+		}
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// errNoCredentials is returned by Authenticate when the request carries
+// no usable Authorization header.
+var errNoCredentials = errors.New("no valid credentials")
+
+// Principal identifies the caller an Authorizer has resolved from a
+// request.
+type Principal struct {
+	Subject string
+	Roles   []string
+}
+
+// Authorizer resolves the caller for a request, either via a static
+// bearer token or an OIDC ID token, so per-job access rules can be
+// enforced in JobConfig.Allowed.
+type Authorizer struct {
+	bearerTokens map[string]Principal
+	oidcVerifier *oidc.IDTokenVerifier
+}
+
+// newAuthorizer builds an Authorizer from config. OIDC discovery is
+// skipped entirely when no issuer is configured, so bearer-token-only
+// deployments don't pay for it.
+func newAuthorizer(ctx context.Context, cfg *Config) (*Authorizer, error) {
+	auth := &Authorizer{bearerTokens: parseBearerTokens(cfg.AuthBearerTokens)}
+
+	if cfg.OIDCIssuer == "" {
+		return auth, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.OIDCIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovering OIDC issuer %s: %w", cfg.OIDCIssuer, err)
+	}
+	auth.oidcVerifier = provider.Verifier(&oidc.Config{ClientID: cfg.OIDCClientID})
+
+	return auth, nil
+}
+
+// parseBearerTokens parses a comma-separated "token:subject" list into
+// a lookup table.
+func parseBearerTokens(raw string) map[string]Principal {
+	tokens := make(map[string]Principal)
+	if raw == "" {
+		return tokens
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		tokens[parts[0]] = Principal{Subject: parts[1]}
+	}
+
+	return tokens
+}
+
+// Authenticate resolves the caller from the request's Authorization
+// header, trying a static bearer token first and falling back to OIDC.
+func (a *Authorizer) Authenticate(r *http.Request) (Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, errNoCredentials
+	}
+
+	if principal, ok := a.bearerTokens[token]; ok {
+		return principal, nil
+	}
+
+	if a.oidcVerifier == nil {
+		return Principal{}, errNoCredentials
+	}
+
+	idToken, err := a.oidcVerifier.Verify(r.Context(), token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("verifying OIDC token: %w", err)
+	}
+
+	var claims struct {
+		Roles []string `json:"roles"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return Principal{}, fmt.Errorf("reading OIDC claims: %w", err)
+	}
+
+	return Principal{Subject: idToken.Subject, Roles: claims.Roles}, nil
+}
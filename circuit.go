@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"
+	circuitOpen     circuitState = "open"
+	circuitHalfOpen circuitState = "half_open"
+)
+
+var (
+	autoRestartTriggeredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auto_restart_triggered_total",
+			Help: "Total number of auto-restarts triggered by the self-healing watcher",
+		},
+		[]string{"reason"},
+	)
+	serviceConsecutiveFailures = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "service_consecutive_failures",
+			Help: "Current number of consecutive failed health checks against ServiceURL",
+		},
+	)
+	circuitStateGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "circuit_state",
+			Help: "Whether the circuit breaker is currently in this state (1) or not (0)",
+		},
+		[]string{"state"},
+	)
+)
+
+// CircuitBreaker watches ServiceURL and auto-restarts JobName after N
+// consecutive failures. A token bucket rate-limits how often it will
+// fire, and a per-hour cap trips it open, pausing further attempts.
+// Once the cooldown has elapsed, an open circuit goes half-open and a
+// single trial probe either closes it or re-opens it; Reset closes it
+// immediately.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	state               circuitState
+	consecutiveFailures int
+	restartsThisHour    int
+	hourWindowStart     time.Time
+	openedAt            time.Time
+
+	jobName            string
+	serviceURL         string
+	failureThreshold   int
+	checkInterval      time.Duration
+	cooldown           time.Duration
+	maxRestartsPerHour int
+	webhookURL         string
+
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+func newCircuitBreaker(cfg *Config) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:              circuitClosed,
+		hourWindowStart:    time.Now(),
+		jobName:            cfg.JobName,
+		serviceURL:         cfg.ServiceURL,
+		failureThreshold:   cfg.CircuitFailureThreshold,
+		checkInterval:      cfg.CircuitCheckInterval,
+		cooldown:           cfg.CircuitCooldown,
+		maxRestartsPerHour: cfg.CircuitMaxRestartsPerHour,
+		webhookURL:         cfg.CircuitWebhookURL,
+		client:             &http.Client{Timeout: 5 * time.Second},
+		limiter:            rate.NewLimiter(rate.Every(cfg.CircuitCooldown), 1),
+	}
+}
+
+// Start runs the watch loop until ctx is cancelled.
+func (cb *CircuitBreaker) Start(ctx context.Context) {
+	ticker := time.NewTicker(cb.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cb.check(ctx)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) check(ctx context.Context) {
+	cb.mu.Lock()
+	if cb.state == circuitOpen && time.Since(cb.openedAt) >= cb.cooldown {
+		cb.state = circuitHalfOpen
+		logger.Info("Circuit entering half-open state to re-probe service", "job", cb.jobName)
+	}
+	state := cb.state
+	cb.mu.Unlock()
+
+	if state == circuitOpen {
+		// Still cooling down; don't spend a probe until the trial window opens.
+		cb.reportState()
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	healthy := cb.probeService(checkCtx)
+
+	cb.mu.Lock()
+	if healthy {
+		cb.consecutiveFailures = 0
+		if cb.state == circuitHalfOpen {
+			cb.state = circuitClosed
+			logger.Info("Circuit closed after successful half-open probe", "job", cb.jobName)
+		}
+	} else {
+		cb.consecutiveFailures++
+		if cb.state == circuitHalfOpen {
+			cb.state = circuitOpen
+			cb.openedAt = time.Now()
+			logger.Warn("Half-open probe failed, circuit re-opened", "job", cb.jobName)
+		}
+	}
+	failures := cb.consecutiveFailures
+	state = cb.state
+	cb.mu.Unlock()
+
+	serviceConsecutiveFailures.Set(float64(failures))
+	cb.reportState()
+
+	if state != circuitClosed || failures < cb.failureThreshold {
+		return
+	}
+
+	cb.trigger(ctx)
+}
+
+func (cb *CircuitBreaker) probeService(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cb.serviceURL, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := cb.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (cb *CircuitBreaker) trigger(ctx context.Context) {
+	cb.mu.Lock()
+	if time.Since(cb.hourWindowStart) > time.Hour {
+		cb.hourWindowStart = time.Now()
+		cb.restartsThisHour = 0
+	}
+
+	if cb.restartsThisHour >= cb.maxRestartsPerHour {
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+		cb.mu.Unlock()
+
+		autoRestartTriggeredTotal.WithLabelValues("circuit_open").Inc()
+		cb.reportState()
+		cb.page(ctx, fmt.Sprintf("circuit opened for %s: exceeded %d restarts/hour", cb.jobName, cb.maxRestartsPerHour))
+		return
+	}
+
+	if !cb.limiter.Allow() {
+		cb.mu.Unlock()
+		return
+	}
+
+	cb.restartsThisHour++
+	cb.consecutiveFailures = 0
+	cb.mu.Unlock()
+
+	logger.Info("Auto-restart triggered by consecutive failures", "job", cb.jobName, "threshold", cb.failureThreshold)
+
+	result, err := orchestrator.Restart(ctx, cb.jobName)
+	if err != nil || len(result.Failures) > 0 {
+		autoRestartTriggeredTotal.WithLabelValues("restart_failed").Inc()
+		cb.page(ctx, fmt.Sprintf("auto-restart of %s failed: %v", cb.jobName, err))
+		return
+	}
+
+	autoRestartTriggeredTotal.WithLabelValues("consecutive_failures").Inc()
+}
+
+func (cb *CircuitBreaker) page(ctx context.Context, message string) {
+	if cb.webhookURL == "" {
+		return
+	}
+
+	body, _ := json.Marshal(map[string]string{"job": cb.jobName, "message": message})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cb.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		logger.Warn("Failed to build circuit webhook request", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := cb.client.Do(req)
+	if err != nil {
+		logger.Warn("Circuit webhook call failed", "error", err)
+		return
+	}
+	defer resp.Body.Close()
+}
+
+func (cb *CircuitBreaker) reportState() {
+	cb.mu.Lock()
+	state := cb.state
+	cb.mu.Unlock()
+
+	for _, s := range []circuitState{circuitClosed, circuitHalfOpen, circuitOpen} {
+		value := 0.0
+		if s == state {
+			value = 1.0
+		}
+		circuitStateGauge.WithLabelValues(string(s)).Set(value)
+	}
+}
+
+// Reset manually closes the circuit, as requested via POST /circuit/reset.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	cb.state = circuitClosed
+	cb.consecutiveFailures = 0
+	cb.restartsThisHour = 0
+	cb.hourWindowStart = time.Now()
+	cb.openedAt = time.Time{}
+	cb.mu.Unlock()
+
+	serviceConsecutiveFailures.Set(0)
+	cb.reportState()
+}
+
+func circuitResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendErrorResponse(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	circuitBreaker.Reset()
+	logger.Info("Circuit breaker manually reset", "remote_addr", r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "reset"})
+}
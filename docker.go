@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// DockerOrchestrator restarts a container by name via the Docker
+// Engine API, for single-host deployments that run Jellyfin directly
+// in a container instead of on Nomad.
+type DockerOrchestrator struct {
+	cli *client.Client
+}
+
+func newDockerOrchestrator() (*DockerOrchestrator, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("creating docker client: %w", err)
+	}
+
+	return &DockerOrchestrator{cli: cli}, nil
+}
+
+func (o *DockerOrchestrator) Restart(ctx context.Context, job string) (RestartResult, error) {
+	if err := o.cli.ContainerRestart(ctx, job, container.StopOptions{}); err != nil {
+		return RestartResult{}, fmt.Errorf("restarting container %s: %w", job, err)
+	}
+
+	return RestartResult{JobName: job, Status: "restarted"}, nil
+}
+
+func (o *DockerOrchestrator) Status(ctx context.Context, job string) (JobStatus, error) {
+	info, err := o.cli.ContainerInspect(ctx, job)
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("inspecting container %s: %w", job, err)
+	}
+
+	return JobStatus{JobName: job, Status: info.State.Status}, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JobConfig describes one managed job as declared in jobs.yaml.
+type JobConfig struct {
+	Name         string   `yaml:"name"`
+	Orchestrator string   `yaml:"orchestrator"`
+	AllowedUsers []string `yaml:"allowed_users"`
+	AllowedRoles []string `yaml:"allowed_roles"`
+	CooldownRaw  string   `yaml:"cooldown"`
+	PreWebhook   string   `yaml:"pre_restart_webhook"`
+	PostWebhook  string   `yaml:"post_restart_webhook"`
+
+	Cooldown time.Duration `yaml:"-"`
+}
+
+// JobsConfig is the top-level shape of jobs.yaml.
+type JobsConfig struct {
+	Jobs []JobConfig `yaml:"jobs"`
+}
+
+// loadJobsConfig reads and validates jobs.yaml. A missing file is not
+// an error — it just means no jobs are managed beyond the legacy
+// single-job routes.
+func loadJobsConfig(path string) (*JobsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &JobsConfig{}, nil
+		}
+		return nil, fmt.Errorf("reading jobs config %s: %w", path, err)
+	}
+
+	var cfg JobsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing jobs config %s: %w", path, err)
+	}
+
+	for i := range cfg.Jobs {
+		if cfg.Jobs[i].CooldownRaw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(cfg.Jobs[i].CooldownRaw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing cooldown for job %s: %w", cfg.Jobs[i].Name, err)
+		}
+		cfg.Jobs[i].Cooldown = d
+	}
+
+	return &cfg, nil
+}
+
+// Allowed reports whether principal may restart or inspect this job.
+// A job with no allowed users/roles configured is open to any
+// authenticated caller.
+func (j JobConfig) Allowed(principal Principal) bool {
+	if len(j.AllowedUsers) == 0 && len(j.AllowedRoles) == 0 {
+		return true
+	}
+
+	for _, user := range j.AllowedUsers {
+		if user == principal.Subject {
+			return true
+		}
+	}
+
+	for _, role := range j.AllowedRoles {
+		for _, principalRole := range principal.Roles {
+			if role == principalRole {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// JobRegistry holds the jobs declared in jobs.yaml along with the
+// Orchestrator backend built for each one, and tracks per-job cooldowns.
+type JobRegistry struct {
+	mu            sync.Mutex
+	jobs          map[string]JobConfig
+	orchestrators map[string]Orchestrator
+	lastRestart   map[string]time.Time
+}
+
+// newJobRegistry builds an orchestrator for each configured job,
+// inheriting the backend's connection details (Nomad address, token,
+// region, etc.) from the base service config.
+func newJobRegistry(jobsCfg *JobsConfig, base *Config) (*JobRegistry, error) {
+	reg := &JobRegistry{
+		jobs:          make(map[string]JobConfig),
+		orchestrators: make(map[string]Orchestrator),
+		lastRestart:   make(map[string]time.Time),
+	}
+
+	for _, job := range jobsCfg.Jobs {
+		backendCfg := *base
+		backendCfg.OrchestratorBackend = job.Orchestrator
+
+		orch, err := newOrchestrator(&backendCfg)
+		if err != nil {
+			return nil, fmt.Errorf("building orchestrator for job %s: %w", job.Name, err)
+		}
+
+		reg.jobs[job.Name] = job
+		reg.orchestrators[job.Name] = orch
+	}
+
+	return reg, nil
+}
+
+// Get returns the JobConfig and Orchestrator registered for name.
+func (r *JobRegistry) Get(name string) (JobConfig, Orchestrator, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return JobConfig{}, nil, false
+	}
+	return job, r.orchestrators[name], true
+}
+
+// Names returns all managed job names, sorted.
+func (r *JobRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCooldown reports whether name is still cooling down from its
+// last restart. If it isn't, now is recorded as the new last-restart
+// time and the caller may proceed.
+func (r *JobRegistry) checkCooldown(name string, cooldown time.Duration, now time.Time) (remaining time.Duration, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cooldown > 0 {
+		if last, seen := r.lastRestart[name]; seen {
+			if remaining := cooldown - now.Sub(last); remaining > 0 {
+				return remaining, false
+			}
+		}
+	}
+
+	r.lastRestart[name] = now
+	return 0, true
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// notifyWebhook best-effort POSTs a small JSON payload to url. A
+// webhook failure is logged but never blocks the restart it's
+// attached to.
+func notifyWebhook(ctx context.Context, url, job, event string) {
+	body, _ := json.Marshal(map[string]string{"job": job, "event": event})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		logger.Warn("Failed to build webhook request", "url", url, "event", event, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Warn("Webhook call failed", "url", url, "event", event, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		logger.Warn("Webhook call returned non-2xx", "url", url, "event", event, "status", resp.StatusCode)
+	}
+}
+
+func jobsListHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"jobs": jobRegistry.Names()})
+}
+
+func jobRestartHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	job, orch, ok := jobRegistry.Get(name)
+	if !ok {
+		sendErrorResponse(w, "Unknown job", http.StatusNotFound)
+		return
+	}
+
+	principal, err := authorizer.Authenticate(r)
+	if err != nil || !job.Allowed(principal) {
+		logger.Warn("Unauthorized restart attempt", "job", name, "remote_addr", r.RemoteAddr)
+		sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	done, ok := beginRestart()
+	if !ok {
+		sendDrainingResponse(w)
+		return
+	}
+	defer done()
+
+	start := time.Now()
+	if remaining, ok := jobRegistry.checkCooldown(name, job.Cooldown, start); !ok {
+		sendErrorResponse(w, fmt.Sprintf("Job %s is cooling down, retry in %s", name, remaining.Round(time.Second)), http.StatusTooManyRequests)
+		return
+	}
+
+	logger.Info("Received request to restart job", "job", name, "user", principal.Subject, "remote_addr", r.RemoteAddr)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if job.PreWebhook != "" {
+		notifyWebhook(ctx, job.PreWebhook, name, "pre-restart")
+	}
+
+	result, restartErr := orch.Restart(ctx, name)
+	duration := time.Since(start)
+	restartDuration.Observe(duration.Seconds())
+
+	auditResult := "success"
+	if restartErr != nil || len(result.Failures) > 0 {
+		auditResult = "error"
+		restartTotal.WithLabelValues("error").Inc()
+		logger.Error("Failed to restart job", "job", name, "error", restartErr, "duration", duration)
+	} else {
+		restartTotal.WithLabelValues("success").Inc()
+		logger.Info("Job restarted successfully", "job", name, "duration", duration)
+	}
+
+	if err := auditLog.Record(AuditEntry{
+		Time:     start,
+		Job:      name,
+		User:     principal.Subject,
+		Result:   auditResult,
+		Duration: duration.String(),
+	}); err != nil {
+		logger.Warn("Failed to record audit entry", "job", name, "error", err)
+	}
+
+	if job.PostWebhook != "" {
+		notifyWebhook(ctx, job.PostWebhook, name, "post-restart")
+	}
+
+	if restartErr != nil {
+		sendErrorResponse(w, "Failed to restart job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func jobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	job, orch, ok := jobRegistry.Get(name)
+	if !ok {
+		sendErrorResponse(w, "Unknown job", http.StatusNotFound)
+		return
+	}
+
+	principal, err := authorizer.Authenticate(r)
+	if err != nil || !job.Allowed(principal) {
+		logger.Warn("Unauthorized status request", "job", name, "remote_addr", r.RemoteAddr)
+		sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	status, err := orch.Status(ctx, name)
+	if err != nil {
+		logger.Error("Failed to get job status", "job", name, "error", err)
+		sendErrorResponse(w, "Failed to get job status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func jobHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	job, _, ok := jobRegistry.Get(name)
+	if !ok {
+		sendErrorResponse(w, "Unknown job", http.StatusNotFound)
+		return
+	}
+
+	principal, err := authorizer.Authenticate(r)
+	if err != nil || !job.Allowed(principal) {
+		logger.Warn("Unauthorized history request", "job", name, "remote_addr", r.RemoteAddr)
+		sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := auditLog.History(name, 100)
+	if err != nil {
+		logger.Error("Failed to read audit history", "job", name, "error", err)
+		sendErrorResponse(w, "Failed to read history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]AuditEntry{"history": entries})
+}
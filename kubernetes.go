@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// KubernetesOrchestrator triggers a rollout restart of a Deployment by
+// patching its pod template annotation, mirroring `kubectl rollout
+// restart`.
+type KubernetesOrchestrator struct {
+	clientset *kubernetes.Clientset
+	namespace string
+}
+
+func newKubernetesOrchestrator(namespace string) (*KubernetesOrchestrator, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading in-cluster config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	return &KubernetesOrchestrator{clientset: clientset, namespace: namespace}, nil
+}
+
+func (o *KubernetesOrchestrator) Restart(ctx context.Context, job string) (RestartResult, error) {
+	patch := []byte(fmt.Sprintf(
+		`{"spec":{"template":{"metadata":{"annotations":{"jellyfin-restarter/restartedAt":"%s"}}}}}`,
+		time.Now().UTC().Format(time.RFC3339),
+	))
+
+	deployments := o.clientset.AppsV1().Deployments(o.namespace)
+	if _, err := deployments.Patch(ctx, job, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return RestartResult{}, fmt.Errorf("patching deployment %s: %w", job, err)
+	}
+
+	return RestartResult{JobName: job, Status: "rollout-triggered"}, nil
+}
+
+func (o *KubernetesOrchestrator) Status(ctx context.Context, job string) (JobStatus, error) {
+	deployment, err := o.clientset.AppsV1().Deployments(o.namespace).Get(ctx, job, metav1.GetOptions{})
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("getting deployment %s: %w", job, err)
+	}
+
+	status := "progressing"
+	if deployment.Status.UpdatedReplicas == deployment.Status.Replicas &&
+		deployment.Status.AvailableReplicas == deployment.Status.Replicas {
+		status = "available"
+	}
+
+	return JobStatus{JobName: job, Status: status}, nil
+}
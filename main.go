@@ -5,36 +5,56 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"runtime"
-	"strings"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"golang.org/x/time/rate"
 )
 
 type Config struct {
-	Port         string
-	NomadAddr    string
-	NomadBinary  string
-	JobName      string
-	ServiceURL   string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
-}
-
-type HealthStatus struct {
-	Time          string `json:"time"`
-	GoVersion     string `json:"go_version"`
-	NomadStatus   string `json:"nomad_status"`
-	ServiceStatus string `json:"service_status"`
+	Port                string
+	OrchestratorBackend string
+	NomadAddr           string
+	NomadToken          string
+	NomadRegion         string
+	NomadNamespace      string
+	NomadCACert         string
+	NomadTLSSkipVerify  bool
+	KubernetesNamespace string
+	JobName             string
+	ServiceURL          string
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	IdleTimeout         time.Duration
+	JobsConfigPath      string
+	AuditLogPath        string
+	AuthBearerTokens    string
+	OIDCIssuer          string
+	OIDCClientID        string
+	ProbesConfigPath    string
+
+	RateLimitBackend   string
+	RateLimitRedisAddr string
+	RateLimitLRUSize   int
+	TrustedProxyCIDRs  string
+	EndpointQuotas     string
+
+	DrainTimeout  time.Duration
+	RouteTimeouts string
+
+	CircuitBreakerEnabled     bool
+	CircuitFailureThreshold   int
+	CircuitCheckInterval      time.Duration
+	CircuitCooldown           time.Duration
+	CircuitMaxRestartsPerHour int
+	CircuitWebhookURL         string
 }
 
 type ErrorResponse struct {
@@ -44,10 +64,17 @@ type ErrorResponse struct {
 }
 
 var (
-	config  *Config
-	logger  *slog.Logger
-	limiter *rate.Limiter
-	server  *http.Server
+	config           *Config
+	logger           *slog.Logger
+	server           *http.Server
+	orchestrator     Orchestrator
+	jobRegistry      *JobRegistry
+	authorizer       *Authorizer
+	auditLog         *AuditLog
+	prober           *Prober
+	circuitBreaker   *CircuitBreaker
+	rateLimitBackend RateLimitBackend
+	trustedProxyNets []*net.IPNet
 
 	// Prometheus metrics
 	restartTotal = prometheus.NewCounterVec(
@@ -79,15 +106,73 @@ func main() {
 	slog.SetDefault(logger)
 
 	config = loadConfig()
-	limiter = rate.NewLimiter(rate.Every(time.Second), 10)
+	trustedProxyNets = parseTrustedProxies(config.TrustedProxyCIDRs)
+	routeTimeouts = parseRouteTimeouts(config.RouteTimeouts)
+	endpointQuotas = parseEndpointQuotas(config.EndpointQuotas)
+
+	var err error
+	rateLimitBackend, err = newRateLimitBackend(config)
+	if err != nil {
+		logger.Error("Failed to build rate limit backend", "backend", config.RateLimitBackend, "error", err)
+		os.Exit(1)
+	}
+
+	orchestrator, err = newOrchestrator(config)
+	if err != nil {
+		logger.Error("Failed to build orchestrator backend", "backend", config.OrchestratorBackend, "error", err)
+		os.Exit(1)
+	}
 
-	prometheus.MustRegister(restartTotal, restartDuration, healthCheckTotal)
+	jobsConfig, err := loadJobsConfig(config.JobsConfigPath)
+	if err != nil {
+		logger.Error("Failed to load jobs config", "path", config.JobsConfigPath, "error", err)
+		os.Exit(1)
+	}
+	jobRegistry, err = newJobRegistry(jobsConfig, config)
+	if err != nil {
+		logger.Error("Failed to build job registry", "error", err)
+		os.Exit(1)
+	}
+
+	authorizer, err = newAuthorizer(context.Background(), config)
+	if err != nil {
+		logger.Error("Failed to build authorizer", "error", err)
+		os.Exit(1)
+	}
+	auditLog = newAuditLog(config.AuditLogPath)
+
+	probesConfig, err := loadProbesConfig(config.ProbesConfigPath)
+	if err != nil {
+		logger.Error("Failed to load probes config", "path", config.ProbesConfigPath, "error", err)
+		os.Exit(1)
+	}
+	prober = newProber(probesConfig)
+
+	probeCtx, cancelProbes := context.WithCancel(context.Background())
+	defer cancelProbes()
+	prober.Start(probeCtx)
+
+	circuitBreaker = newCircuitBreaker(config)
+	if config.CircuitBreakerEnabled {
+		circuitCtx, cancelCircuit := context.WithCancel(context.Background())
+		defer cancelCircuit()
+		go circuitBreaker.Start(circuitCtx)
+	}
+
+	prometheus.MustRegister(restartTotal, restartDuration, healthCheckTotal, probeDurationSeconds,
+		autoRestartTriggeredTotal, serviceConsecutiveFailures, circuitStateGauge, rateLimitDroppedTotal)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", rateLimitMiddleware(homeHandler))
-	mux.HandleFunc("/restart", rateLimitMiddleware(restartHandler))
-	mux.HandleFunc("/health", rateLimitMiddleware(healthHandler))
-	mux.HandleFunc("/healthz", rateLimitMiddleware(healthzHandler))
+	mux.HandleFunc("/", keyedRateLimitMiddleware("/", withTimeout("/", homeHandler)))
+	mux.HandleFunc("/restart", keyedRateLimitMiddleware("/restart", withTimeout("/restart", restartHandler)))
+	mux.HandleFunc("/health", keyedRateLimitMiddleware("/health", withTimeout("/health", healthHandler)))
+	mux.HandleFunc("/healthz", keyedRateLimitMiddleware("/healthz", withTimeout("/healthz", healthzHandler)))
+	mux.HandleFunc("GET /probes/{name}", keyedRateLimitMiddleware("/probes", withTimeout("/probes", probeTimelineHandler)))
+	mux.HandleFunc("GET /jobs", keyedRateLimitMiddleware("/jobs", withTimeout("/jobs", jobsListHandler)))
+	mux.HandleFunc("POST /jobs/{name}/restart", keyedRateLimitMiddleware("/restart", withTimeout("/restart", jobRestartHandler)))
+	mux.HandleFunc("GET /jobs/{name}/status", keyedRateLimitMiddleware("/jobs", withTimeout("/jobs", jobStatusHandler)))
+	mux.HandleFunc("GET /jobs/{name}/history", keyedRateLimitMiddleware("/jobs", withTimeout("/jobs", jobHistoryHandler)))
+	mux.HandleFunc("POST /circuit/reset", keyedRateLimitMiddleware("/circuit/reset", withTimeout("/circuit/reset", circuitResetHandler)))
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
 
@@ -112,6 +197,9 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server")
+	beginDraining()
+	waitForDrain(config.DrainTimeout)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -125,14 +213,42 @@ func main() {
 
 func loadConfig() *Config {
 	return &Config{
-		Port:         getEnv("PORT", "8888"),
-		NomadAddr:    getEnv("NOMAD_ADDR", "http://consul.service.starnix.net:4646"),
-		NomadBinary:  getEnv("NOMAD_BINARY", "/usr/local/bin/nomad"),
-		JobName:      getEnv("JOB_NAME", "jellyfin"),
-		ServiceURL:   getEnv("SERVICE_URL", "https://jellyfin.service.starnix.net"),
-		ReadTimeout:  parseDuration(getEnv("READ_TIMEOUT", "10s")),
-		WriteTimeout: parseDuration(getEnv("WRITE_TIMEOUT", "10s")),
-		IdleTimeout:  parseDuration(getEnv("IDLE_TIMEOUT", "60s")),
+		Port:                getEnv("PORT", "8888"),
+		OrchestratorBackend: getEnv("ORCHESTRATOR", "nomad"),
+		NomadAddr:           getEnv("NOMAD_ADDR", "http://consul.service.starnix.net:4646"),
+		NomadToken:          getEnv("NOMAD_TOKEN", ""),
+		NomadRegion:         getEnv("NOMAD_REGION", ""),
+		NomadNamespace:      getEnv("NOMAD_NAMESPACE", ""),
+		NomadCACert:         getEnv("NOMAD_CACERT", ""),
+		NomadTLSSkipVerify:  getEnvBool("NOMAD_TLS_SKIP_VERIFY", false),
+		KubernetesNamespace: getEnv("KUBERNETES_NAMESPACE", "default"),
+		JobName:             getEnv("JOB_NAME", "jellyfin"),
+		ServiceURL:          getEnv("SERVICE_URL", "https://jellyfin.service.starnix.net"),
+		ReadTimeout:         parseDuration(getEnv("READ_TIMEOUT", "10s")),
+		WriteTimeout:        parseDuration(getEnv("WRITE_TIMEOUT", "10s")),
+		IdleTimeout:         parseDuration(getEnv("IDLE_TIMEOUT", "60s")),
+		JobsConfigPath:      getEnv("JOBS_CONFIG", "jobs.yaml"),
+		AuditLogPath:        getEnv("AUDIT_LOG_PATH", "audit.jsonl"),
+		AuthBearerTokens:    getEnv("AUTH_BEARER_TOKENS", ""),
+		OIDCIssuer:          getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:        getEnv("OIDC_CLIENT_ID", ""),
+		ProbesConfigPath:    getEnv("PROBES_CONFIG", "probes.yaml"),
+
+		RateLimitBackend:   getEnv("RATE_LIMIT_BACKEND", "memory"),
+		RateLimitRedisAddr: getEnv("RATE_LIMIT_REDIS_ADDR", ""),
+		RateLimitLRUSize:   getEnvInt("RATE_LIMIT_LRU_SIZE", 10000),
+		TrustedProxyCIDRs:  getEnv("TRUSTED_PROXY_CIDRS", ""),
+		EndpointQuotas:     getEnv("ENDPOINT_QUOTAS", "/restart=1m:1,/healthz=1s:60,default=1s:5"),
+
+		DrainTimeout:  parseDuration(getEnv("DRAIN_TIMEOUT", "60s")),
+		RouteTimeouts: getEnv("ROUTE_TIMEOUTS", "/restart=90s,/healthz=12s,default=30s"),
+
+		CircuitBreakerEnabled:     getEnvBool("CIRCUIT_BREAKER_ENABLED", false),
+		CircuitFailureThreshold:   getEnvInt("CIRCUIT_FAILURE_THRESHOLD", 3),
+		CircuitCheckInterval:      parseDuration(getEnv("CIRCUIT_CHECK_INTERVAL", "30s")),
+		CircuitCooldown:           parseDuration(getEnv("CIRCUIT_COOLDOWN", "5m")),
+		CircuitMaxRestartsPerHour: getEnvInt("CIRCUIT_MAX_RESTARTS_PER_HOUR", 3),
+		CircuitWebhookURL:         getEnv("CIRCUIT_WEBHOOK_URL", ""),
 	}
 }
 
@@ -143,6 +259,32 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Warn("Invalid boolean, using default", "input", value, "default", defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Warn("Invalid integer, using default", "input", value, "default", defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -152,17 +294,6 @@ func parseDuration(s string) time.Duration {
 	return d
 }
 
-func rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if !limiter.Allow() {
-			logger.Warn("Rate limit exceeded", "remote_addr", r.RemoteAddr)
-			sendErrorResponse(w, "Rate limit exceeded", http.StatusTooManyRequests)
-			return
-		}
-		next(w, r)
-	}
-}
-
 func sendErrorResponse(w http.ResponseWriter, message string, code int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -187,37 +318,47 @@ func restartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	done, ok := beginRestart()
+	if !ok {
+		sendDrainingResponse(w)
+		return
+	}
+	defer done()
+
+	principal, err := authorizer.Authenticate(r)
+	if err != nil {
+		logger.Warn("Unauthorized restart attempt", "job", config.JobName, "remote_addr", r.RemoteAddr)
+		sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	start := time.Now()
-	logger.Info("Received request to restart job", "job", config.JobName, "remote_addr", r.RemoteAddr)
+	logger.Info("Received request to restart job", "job", config.JobName, "backend", config.OrchestratorBackend, "user", principal.Subject, "remote_addr", r.RemoteAddr)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	restartCmd := exec.CommandContext(ctx, config.NomadBinary, "job", "restart", "-yes", "-verbose", config.JobName)
-	restartCmd.Env = append(os.Environ(), "NOMAD_ADDR="+config.NomadAddr)
-
-	output, err := restartCmd.CombinedOutput()
+	result, err := orchestrator.Restart(ctx, config.JobName)
 	duration := time.Since(start)
 	restartDuration.Observe(duration.Seconds())
 
 	if err != nil {
 		restartTotal.WithLabelValues("error").Inc()
-		logger.Error("Failed to restart job", "job", config.JobName, "error", err, "output", string(output), "duration", duration)
+		logger.Error("Failed to restart job", "job", config.JobName, "error", err, "duration", duration)
 		sendErrorResponse(w, "Failed to restart job", http.StatusInternalServerError)
 		return
 	}
 
-	restartTotal.WithLabelValues("success").Inc()
-	logger.Info("Job restarted successfully", "job", config.JobName, "duration", duration)
+	if len(result.Failures) > 0 {
+		restartTotal.WithLabelValues("error").Inc()
+		logger.Error("Job restart completed with failures", "job", config.JobName, "status", result.Status, "failures", result.Failures, "duration", duration)
+	} else {
+		restartTotal.WithLabelValues("success").Inc()
+		logger.Info("Job restarted successfully", "job", config.JobName, "status", result.Status, "duration", duration)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":  true,
-		"message":  "Job restarted successfully",
-		"job":      config.JobName,
-		"output":   string(output),
-		"duration": duration.String(),
-	})
+	json.NewEncoder(w).Encode(result)
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
@@ -237,45 +378,38 @@ func healthzHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	client := &http.Client{Timeout: 5 * time.Second}
+	summary := prober.Summary()
 
-	nomadURL := config.NomadAddr + "/v1/status/leader"
-	nomadStatus := checkEndpoint(ctx, client, nomadURL, "nomad")
-	serviceStatus := checkEndpoint(ctx, client, config.ServiceURL, "service")
-
-	data := HealthStatus{
-		Time:          time.Now().Format(time.RFC3339),
-		GoVersion:     runtime.Version(),
-		NomadStatus:   nomadStatus,
-		ServiceStatus: serviceStatus,
+	allHealthy := true
+	for _, result := range summary {
+		if !result.Success {
+			allHealthy = false
+		}
+		if result.Success {
+			healthCheckTotal.WithLabelValues("healthz", "success").Inc()
+		} else {
+			healthCheckTotal.WithLabelValues("healthz", "error").Inc()
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"time":       time.Now().Format(time.RFC3339),
+		"go_version": runtime.Version(),
+		"healthy":    allHealthy,
+		"probes":     summary,
+	})
 }
 
-func checkEndpoint(ctx context.Context, client *http.Client, url, name string) string {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		healthCheckTotal.WithLabelValues("healthz_"+name, "error").Inc()
-		return fmt.Sprintf("%s is not reachable (request error)", strings.Title(name))
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		healthCheckTotal.WithLabelValues("healthz_"+name, "error").Inc()
-		return fmt.Sprintf("%s is not reachable (connection error)", strings.Title(name))
-	}
-	defer resp.Body.Close()
+func probeTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
 
-	if resp.StatusCode == http.StatusOK {
-		healthCheckTotal.WithLabelValues("healthz_"+name, "success").Inc()
-		return fmt.Sprintf("%s is reachable (HTTP %d)", strings.Title(name), resp.StatusCode)
+	timeline, ok := prober.Timeline(name)
+	if !ok {
+		sendErrorResponse(w, "Unknown probe", http.StatusNotFound)
+		return
 	}
 
-	healthCheckTotal.WithLabelValues("healthz_"+name, "error").Inc()
-	return fmt.Sprintf("%s is not reachable (HTTP %d)", strings.Title(name), resp.StatusCode)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"probe": name, "results": timeline})
 }
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -24,23 +25,39 @@ func TestMain(m *testing.M) {
 	slog.SetDefault(logger)
 	
 	config = &Config{
-		Port:         "8888",
-		NomadAddr:    "http://test-nomad:4646",
-		NomadBinary:  "/bin/echo", // Use echo for testing
-		JobName:      "test-job",
-		ServiceURL:   "http://test-service",
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Port:                "8888",
+		OrchestratorBackend: "nomad",
+		NomadAddr:           "http://test-nomad:4646",
+		JobName:             "test-job",
+		ServiceURL:          "http://test-service",
+		ReadTimeout:         10 * time.Second,
+		WriteTimeout:        10 * time.Second,
+		IdleTimeout:         60 * time.Second,
+		RateLimitBackend:    "memory",
+		RateLimitLRUSize:    1000,
+		EndpointQuotas:      "/restart=1m:1,/healthz=1s:60,default=1s:5",
+		DrainTimeout:        5 * time.Second,
+		RouteTimeouts:       "/restart=90s,/healthz=12s,default=30s",
 	}
-	
-	limiter = rate.NewLimiter(rate.Every(time.Second), 10)
+
+	trustedProxyNets = parseTrustedProxies(config.TrustedProxyCIDRs)
+	routeTimeouts = parseRouteTimeouts(config.RouteTimeouts)
+	endpointQuotas = parseEndpointQuotas(config.EndpointQuotas)
+	rateLimitBackend, _ = newRateLimitBackend(config)
+	orchestrator, _ = newOrchestrator(config)
+
+	jobRegistry, _ = newJobRegistry(&JobsConfig{}, config)
+	authorizer, _ = newAuthorizer(context.Background(), config)
+	auditLog = newAuditLog("test_audit.jsonl")
+	prober = newProber(&ProbesConfig{})
+	circuitBreaker = newCircuitBreaker(config)
 	
 	// Reset metrics for each test
 	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 	prometheus.MustRegister(restartTotal, restartDuration, healthCheckTotal)
 	
 	code := m.Run()
+	os.Remove("test_audit.jsonl")
 	os.Exit(code)
 }
 
@@ -166,135 +183,216 @@ func TestHomeHandler(t *testing.T) {
 }
 
 func TestRestartHandler(t *testing.T) {
+	// Fake Nomad agent: a non-periodic service job whose running
+	// allocation gets restarted in place.
+	nomadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/allocations"):
+			json.NewEncoder(w).Encode([]NomadAllocation{{ID: "alloc-1", ClientStatus: "running"}})
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/v1/job/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"ID": "test-job"})
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/v1/client/allocation/"):
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer nomadServer.Close()
+
+	originalOrchestrator := orchestrator
+	orchestrator, _ = newOrchestrator(&Config{OrchestratorBackend: "nomad", NomadAddr: nomadServer.URL})
+	defer func() { orchestrator = originalOrchestrator }()
+
+	originalAuth := authorizer
+	authorizer, _ = newAuthorizer(context.Background(), &Config{AuthBearerTokens: "testtoken:alice"})
+	defer func() { authorizer = originalAuth }()
+
 	// Test successful restart
 	req := httptest.NewRequest("POST", "/restart", bytes.NewBuffer([]byte{}))
+	req.Header.Set("Authorization", "Bearer testtoken")
 	w := httptest.NewRecorder()
-	
+
 	restartHandler(w, req)
-	
+
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
-	
-	var response map[string]interface{}
+
+	var response RestartResult
 	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 		t.Errorf("Failed to parse JSON response: %v", err)
 	}
-	
-	if success, ok := response["success"].(bool); !ok || !success {
-		t.Errorf("Expected success=true in response")
+
+	if response.Status != "restarted" {
+		t.Errorf("Expected status=restarted in response, got %s", response.Status)
 	}
-	
+
+	// Test unauthenticated request
+	req = httptest.NewRequest("POST", "/restart", bytes.NewBuffer([]byte{}))
+	w = httptest.NewRecorder()
+
+	restartHandler(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without credentials, got %d", w.Code)
+	}
+
 	// Test wrong method
 	req = httptest.NewRequest("GET", "/restart", nil)
 	w = httptest.NewRecorder()
-	
+
 	restartHandler(w, req)
-	
+
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status 405, got %d", w.Code)
 	}
 }
 
+func TestRestartHandlerDraining(t *testing.T) {
+	beginDraining()
+	defer clearDraining()
+
+	req := httptest.NewRequest("POST", "/restart", bytes.NewBuffer([]byte{}))
+	w := httptest.NewRecorder()
+
+	restartHandler(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 while draining, got %d", w.Code)
+	}
+
+	var response ShutdownResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to parse JSON response: %v", err)
+	}
+	if response.Message == "" {
+		t.Error("Expected a non-empty shutdown message")
+	}
+}
+
 func TestHealthzHandler(t *testing.T) {
-	// Create test server for mocking external services
-	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	}))
-	defer testServer.Close()
-	
-	// Update config to use test server
-	originalNomadAddr := config.NomadAddr
-	originalServiceURL := config.ServiceURL
-	config.NomadAddr = testServer.URL
-	config.ServiceURL = testServer.URL
-	
-	defer func() {
-		config.NomadAddr = originalNomadAddr
-		config.ServiceURL = originalServiceURL
-	}()
-	
+	originalProber := prober
+	prober = newProber(&ProbesConfig{})
+	prober.results["service"] = &probeRingBuffer{}
+	prober.results["service"].Add(ProbeResult{Time: time.Now(), Success: true})
+	defer func() { prober = originalProber }()
+
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	w := httptest.NewRecorder()
-	
+
 	healthzHandler(w, req)
-	
+
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
-	
-	var response HealthStatus
+
+	var response struct {
+		GoVersion string                 `json:"go_version"`
+		Healthy   bool                   `json:"healthy"`
+		Probes    map[string]ProbeResult `json:"probes"`
+	}
 	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
 		t.Errorf("Failed to parse JSON response: %v", err)
 	}
-	
+
 	if response.GoVersion == "" {
 		t.Error("Expected GoVersion to be set")
 	}
-	
-	if !strings.Contains(response.NomadStatus, "reachable") {
-		t.Errorf("Expected Nomad to be reachable, got: %s", response.NomadStatus)
+	if !response.Healthy {
+		t.Error("Expected healthy=true when all probes succeed")
+	}
+	if !response.Probes["service"].Success {
+		t.Error("Expected service probe to report success")
 	}
 }
 
-func TestCheckEndpoint(t *testing.T) {
-	// Test successful endpoint
+func TestRunHTTPCheck(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer testServer.Close()
-	
-	ctx := context.Background()
-	client := &http.Client{Timeout: 5 * time.Second}
-	
-	result := checkEndpoint(ctx, client, testServer.URL, "test")
-	if !strings.Contains(result, "reachable") {
-		t.Errorf("Expected endpoint to be reachable, got: %s", result)
+
+	probe := ProbeConfig{Name: "test", Kind: string(ProbeKindHTTP), Target: testServer.URL, ExpectStatus: http.StatusOK}
+	result := runHTTPCheck(context.Background(), probe)
+	if !result.Success {
+		t.Errorf("Expected probe to succeed, got error: %s", result.Error)
 	}
-	
-	// Test unreachable endpoint
-	result = checkEndpoint(ctx, client, "http://invalid-url-12345", "test")
-	if !strings.Contains(result, "not reachable") {
-		t.Errorf("Expected endpoint to be unreachable, got: %s", result)
+
+	result = runHTTPCheck(context.Background(), ProbeConfig{Name: "test", Kind: string(ProbeKindHTTP), Target: "http://invalid-url-12345"})
+	if result.Success {
+		t.Error("Expected probe against an invalid URL to fail")
 	}
 }
 
-func TestRateLimitMiddleware(t *testing.T) {
-	// Create a simple handler
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func TestKeyedRateLimitMiddleware(t *testing.T) {
+	defer func(backend RateLimitBackend) { rateLimitBackend = backend }(rateLimitBackend)
+
+	endpointQuotas["test-endpoint"] = EndpointQuota{Rate: rate.Every(time.Hour), Burst: 1}
+	defer delete(endpointQuotas, "test-endpoint")
+
+	var err error
+	rateLimitBackend, err = newMemoryRateLimitBackend(10)
+	if err != nil {
+		t.Fatalf("newMemoryRateLimitBackend: %v", err)
+	}
+
+	handler := keyedRateLimitMiddleware("test-endpoint", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
-	
-	// Create rate limited handler with very low limit
-	testLimiter := rate.NewLimiter(rate.Every(time.Hour), 1) // Only 1 request per hour
-	middleware := func(next http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			if !testLimiter.Allow() {
-				sendErrorResponse(w, "Rate limit exceeded", http.StatusTooManyRequests)
-				return
-			}
-			next(w, r)
-		}
-	}
-	
-	rateLimitedHandler := middleware(handler)
-	
-	// First request should succeed
+
 	req := httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "203.0.113.5:1234"
 	w := httptest.NewRecorder()
-	rateLimitedHandler(w, req)
-	
+	handler(w, req)
 	if w.Code != http.StatusOK {
 		t.Errorf("First request should succeed, got status %d", w.Code)
 	}
-	
-	// Second request should be rate limited
-	req = httptest.NewRequest("GET", "/test", nil)
+
 	w = httptest.NewRecorder()
-	rateLimitedHandler(w, req)
-	
+	handler(w, req)
 	if w.Code != http.StatusTooManyRequests {
-		t.Errorf("Second request should be rate limited, got status %d", w.Code)
+		t.Errorf("Second request from the same IP should be rate limited, got status %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on a rate limited response")
+	}
+
+	// A different client IP gets its own bucket.
+	other := httptest.NewRequest("GET", "/test", nil)
+	other.RemoteAddr = "203.0.113.9:5678"
+	w = httptest.NewRecorder()
+	handler(w, other)
+	if w.Code != http.StatusOK {
+		t.Errorf("Request from a different IP should not be rate limited, got status %d", w.Code)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+	proxies := []*net.IPNet{trusted}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "203.0.113.5:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if ip := clientIP(req, proxies); ip != "203.0.113.5" {
+		t.Errorf("Untrusted peer should not have X-Forwarded-For honored, got %q", ip)
+	}
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 10.1.2.3")
+	if ip := clientIP(req, proxies); ip != "198.51.100.1" {
+		t.Errorf("Trusted peer should have the rightmost untrusted X-Forwarded-For entry honored, got %q", ip)
+	}
+
+	// A caller can put anything it likes in the leftmost slot; only the
+	// entry appended by the trusted proxy (the rightmost one it didn't
+	// already see) should be believed.
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	req.Header.Set("X-Forwarded-For", "9.9.9.9, 198.51.100.1, 10.1.2.3")
+	if ip := clientIP(req, proxies); ip != "198.51.100.1" {
+		t.Errorf("Spoofed leftmost entry should not be honored over the proxy-appended one, got %q", ip)
 	}
 }
 
@@ -318,4 +416,247 @@ func TestSendErrorResponse(t *testing.T) {
 	if response.Code != http.StatusBadRequest {
 		t.Errorf("Expected code 400, got %d", response.Code)
 	}
+}
+
+func TestJobConfigAllowed(t *testing.T) {
+	open := JobConfig{Name: "open-job"}
+	if !open.Allowed(Principal{Subject: "anyone"}) {
+		t.Error("Expected job with no allow-list to permit any caller")
+	}
+
+	restricted := JobConfig{Name: "restricted-job", AllowedUsers: []string{"alice"}, AllowedRoles: []string{"ops"}}
+	if !restricted.Allowed(Principal{Subject: "alice"}) {
+		t.Error("Expected allowed user to be permitted")
+	}
+	if !restricted.Allowed(Principal{Subject: "bob", Roles: []string{"ops"}}) {
+		t.Error("Expected allowed role to be permitted")
+	}
+	if restricted.Allowed(Principal{Subject: "mallory"}) {
+		t.Error("Expected unlisted user to be denied")
+	}
+}
+
+func TestJobRestartHandler(t *testing.T) {
+	nomadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/allocations"):
+			json.NewEncoder(w).Encode([]NomadAllocation{{ID: "alloc-2", ClientStatus: "running"}})
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/v1/job/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"ID": "media"})
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/v1/client/allocation/"):
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer nomadServer.Close()
+
+	originalRegistry := jobRegistry
+	jobsCfg := &JobsConfig{Jobs: []JobConfig{{Name: "media", Orchestrator: "nomad"}}}
+	jobRegistry, _ = newJobRegistry(jobsCfg, &Config{OrchestratorBackend: "nomad", NomadAddr: nomadServer.URL})
+	defer func() { jobRegistry = originalRegistry }()
+
+	originalAuth := authorizer
+	authorizer, _ = newAuthorizer(context.Background(), &Config{AuthBearerTokens: "testtoken:alice"})
+	defer func() { authorizer = originalAuth }()
+
+	originalAudit := auditLog
+	auditLog = newAuditLog("test_job_audit.jsonl")
+	defer func() { auditLog = originalAudit; os.Remove("test_job_audit.jsonl") }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs/{name}/restart", jobRestartHandler)
+
+	req := httptest.NewRequest("POST", "/jobs/media/restart", nil)
+	req.Header.Set("Authorization", "Bearer testtoken")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Missing credentials should be rejected
+	req = httptest.NewRequest("POST", "/jobs/media/restart", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for missing credentials, got %d", w.Code)
+	}
+
+	// Unknown job
+	req = httptest.NewRequest("POST", "/jobs/unknown/restart", nil)
+	req.Header.Set("Authorization", "Bearer testtoken")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown job, got %d", w.Code)
+	}
+}
+
+func TestJobStatusAndHistoryHandlersRequireAuth(t *testing.T) {
+	nomadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ID": "media", "Status": "running"})
+	}))
+	defer nomadServer.Close()
+
+	originalRegistry := jobRegistry
+	jobsCfg := &JobsConfig{Jobs: []JobConfig{{Name: "media", Orchestrator: "nomad"}}}
+	jobRegistry, _ = newJobRegistry(jobsCfg, &Config{OrchestratorBackend: "nomad", NomadAddr: nomadServer.URL})
+	defer func() { jobRegistry = originalRegistry }()
+
+	originalAuth := authorizer
+	authorizer, _ = newAuthorizer(context.Background(), &Config{AuthBearerTokens: "testtoken:alice"})
+	defer func() { authorizer = originalAuth }()
+
+	originalAudit := auditLog
+	auditLog = newAuditLog("test_job_status_audit.jsonl")
+	defer func() { auditLog = originalAudit; os.Remove("test_job_status_audit.jsonl") }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs/{name}/status", jobStatusHandler)
+	mux.HandleFunc("GET /jobs/{name}/history", jobHistoryHandler)
+
+	for _, path := range []string{"/jobs/media/status", "/jobs/media/history"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s without credentials: expected 401, got %d", path, w.Code)
+		}
+
+		req = httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer testtoken")
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s with credentials: expected 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAuditLogRecordAndHistory(t *testing.T) {
+	path := "test_audit_log_history.jsonl"
+	defer os.Remove(path)
+
+	log := newAuditLog(path)
+	if err := log.Record(AuditEntry{Job: "media", User: "alice", Result: "success", Duration: "1s"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := log.Record(AuditEntry{Job: "other", User: "bob", Result: "error", Duration: "2s"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	entries, err := log.History("media", 0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].User != "alice" {
+		t.Errorf("Expected one entry for media by alice, got %+v", entries)
+	}
+}
+
+func TestCircuitBreakerTrigger(t *testing.T) {
+	nomadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/allocations"):
+			json.NewEncoder(w).Encode([]NomadAllocation{{ID: "alloc-3", ClientStatus: "running"}})
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/v1/job/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"ID": "test-job"})
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/v1/client/allocation/"):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer nomadServer.Close()
+
+	downServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer downServer.Close()
+
+	originalOrchestrator := orchestrator
+	orchestrator, _ = newOrchestrator(&Config{OrchestratorBackend: "nomad", NomadAddr: nomadServer.URL})
+	defer func() { orchestrator = originalOrchestrator }()
+
+	cb := newCircuitBreaker(&Config{
+		JobName:                   "media",
+		ServiceURL:                downServer.URL,
+		CircuitFailureThreshold:   2,
+		CircuitCheckInterval:      time.Minute,
+		CircuitCooldown:           0,
+		CircuitMaxRestartsPerHour: 1,
+	})
+
+	ctx := context.Background()
+	cb.check(ctx)
+	if cb.consecutiveFailures != 1 {
+		t.Fatalf("Expected 1 consecutive failure, got %d", cb.consecutiveFailures)
+	}
+
+	cb.check(ctx)
+	if cb.restartsThisHour != 1 {
+		t.Errorf("Expected restart to be triggered after reaching threshold, got restartsThisHour=%d", cb.restartsThisHour)
+	}
+
+	// A second trip within the same hour should open the circuit.
+	cb.consecutiveFailures = 2
+	cb.trigger(ctx)
+	if cb.state != circuitOpen {
+		t.Errorf("Expected circuit to open after exceeding max restarts per hour, got state=%s", cb.state)
+	}
+
+	cb.Reset()
+	if cb.state != circuitClosed {
+		t.Errorf("Expected Reset to close the circuit, got state=%s", cb.state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenRecovery(t *testing.T) {
+	upServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upServer.Close()
+
+	downServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer downServer.Close()
+
+	ctx := context.Background()
+
+	// A recovered service should move the circuit open -> half_open -> closed.
+	cb := newCircuitBreaker(&Config{ServiceURL: upServer.URL, CircuitCooldown: 0})
+	cb.state = circuitOpen
+	cb.openedAt = time.Now().Add(-time.Minute)
+
+	cb.check(ctx)
+	if cb.state != circuitClosed {
+		t.Errorf("Expected circuit to close after a successful half-open probe, got state=%s", cb.state)
+	}
+
+	// A still-failing service should re-open the circuit after its
+	// half-open trial probe fails.
+	cb = newCircuitBreaker(&Config{ServiceURL: downServer.URL, CircuitCooldown: 0, CircuitFailureThreshold: 100})
+	cb.state = circuitOpen
+	cb.openedAt = time.Now().Add(-time.Minute)
+
+	cb.check(ctx)
+	if cb.state != circuitOpen {
+		t.Errorf("Expected circuit to re-open after a failed half-open probe, got state=%s", cb.state)
+	}
+	if time.Since(cb.openedAt) > time.Second {
+		t.Error("Expected openedAt to be reset when re-opening after a failed probe")
+	}
+
+	// While still within the cooldown window, no probe should be spent.
+	cb = newCircuitBreaker(&Config{ServiceURL: upServer.URL, CircuitCooldown: time.Hour})
+	cb.state = circuitOpen
+	cb.openedAt = time.Now()
+
+	cb.check(ctx)
+	if cb.state != circuitOpen {
+		t.Errorf("Expected circuit to remain open during the cooldown window, got state=%s", cb.state)
+	}
 }
\ No newline at end of file
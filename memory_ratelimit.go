@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/time/rate"
+)
+
+// memoryRateLimitBackend keeps one *rate.Limiter per (endpoint, IP) key
+// in a bounded LRU, so a single replica doesn't leak memory for
+// abusive or spoofed clients.
+type memoryRateLimitBackend struct {
+	mu       sync.Mutex
+	limiters *lru.Cache[string, *rate.Limiter]
+}
+
+func newMemoryRateLimitBackend(size int) (*memoryRateLimitBackend, error) {
+	if size <= 0 {
+		size = 10000
+	}
+	cache, err := lru.New[string, *rate.Limiter](size)
+	if err != nil {
+		return nil, fmt.Errorf("creating rate limit cache: %w", err)
+	}
+	return &memoryRateLimitBackend{limiters: cache}, nil
+}
+
+func (b *memoryRateLimitBackend) Allow(ctx context.Context, key string, quota EndpointQuota) (bool, time.Duration, error) {
+	b.mu.Lock()
+	limiter, ok := b.limiters.Get(key)
+	if !ok {
+		limiter = rate.NewLimiter(quota.Rate, quota.Burst)
+		b.limiters.Add(key, limiter)
+	}
+	b.mu.Unlock()
+
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return false, 0, nil
+	}
+
+	delay := reservation.Delay()
+	if delay > 0 {
+		reservation.Cancel()
+		return false, delay, nil
+	}
+
+	return true, 0, nil
+}
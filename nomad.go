@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// NomadClient speaks the Nomad HTTP API directly, so the service no
+// longer depends on a locally installed `nomad` binary.
+type NomadClient struct {
+	addr      string
+	token     string
+	region    string
+	namespace string
+	http      *http.Client
+}
+
+// NomadEvaluation mirrors the subset of Nomad's evaluation response we
+// need to know whether a restart has finished placing allocations.
+type NomadEvaluation struct {
+	ID                string                 `json:"ID"`
+	Status            string                 `json:"Status"`
+	StatusDescription string                 `json:"StatusDescription"`
+	FailedTGAllocs    map[string]interface{} `json:"FailedTGAllocs"`
+}
+
+// NomadJob mirrors the subset of Nomad's job response that lets us
+// report a coarse-grained status for Status().
+type NomadJob struct {
+	ID     string `json:"ID"`
+	Status string `json:"Status"`
+}
+
+// NomadAllocation mirrors the subset of Nomad's allocation response we
+// need to find and restart a service job's running allocations.
+type NomadAllocation struct {
+	ID           string `json:"ID"`
+	ClientStatus string `json:"ClientStatus"`
+}
+
+// newNomadClient builds a NomadClient from config, wiring up TLS
+// verification and a custom CA bundle if one was configured.
+func newNomadClient(cfg *Config) (*NomadClient, error) {
+	transport := &http.Transport{}
+
+	if cfg.NomadCACert != "" || cfg.NomadTLSSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: cfg.NomadTLSSkipVerify}
+
+		if cfg.NomadCACert != "" {
+			pem, err := os.ReadFile(cfg.NomadCACert)
+			if err != nil {
+				return nil, fmt.Errorf("reading nomad CA cert: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("parsing nomad CA cert %s", cfg.NomadCACert)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &NomadClient{
+		addr:      cfg.NomadAddr,
+		token:     cfg.NomadToken,
+		region:    cfg.NomadRegion,
+		namespace: cfg.NomadNamespace,
+		http:      &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (c *NomadClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if body == nil {
+		body = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	if c.region != "" {
+		q.Set("region", c.region)
+	}
+	if c.namespace != "" {
+		q.Set("namespace", c.namespace)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	if c.token != "" {
+		req.Header.Set("X-Nomad-Token", c.token)
+	}
+
+	return req, nil
+}
+
+// ForceRestart submits a restart for a periodic job via the
+// periodic/force endpoint and returns the EvalID tracking the
+// resulting placement. Nomad rejects this call for non-periodic jobs.
+func (c *NomadClient) ForceRestart(ctx context.Context, job string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/job/"+job+"/periodic/force", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling nomad: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("nomad returned %s", resp.Status)
+	}
+
+	var result struct {
+		EvalID string `json:"EvalID"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding nomad response: %w", err)
+	}
+
+	return result.EvalID, nil
+}
+
+// getJobDefinition fetches job's full definition, used by
+// NomadOrchestrator.Restart to tell a periodic job from a service job.
+func (c *NomadClient) getJobDefinition(ctx context.Context, job string) (map[string]interface{}, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/job/"+job, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling nomad: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nomad returned %s", resp.Status)
+	}
+
+	var def map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&def); err != nil {
+		return nil, fmt.Errorf("decoding nomad response: %w", err)
+	}
+
+	return def, nil
+}
+
+// ListAllocations fetches job's current allocations so a restart can
+// target the ones that are actually running.
+func (c *NomadClient) ListAllocations(ctx context.Context, job string) ([]NomadAllocation, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/job/"+job+"/allocations", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling nomad: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nomad returned %s", resp.Status)
+	}
+
+	var allocs []NomadAllocation
+	if err := json.NewDecoder(resp.Body).Decode(&allocs); err != nil {
+		return nil, fmt.Errorf("decoding nomad response: %w", err)
+	}
+
+	return allocs, nil
+}
+
+// RestartAllocation restarts every task in the given allocation in
+// place. This is how `nomad job restart` itself restarts a service
+// job — Nomad has no single "restart this job" endpoint for non-
+// periodic jobs, and re-registering the job definition isn't reliable
+// since the scheduler may see nothing placement-relevant changed and
+// hand back a no-op evaluation.
+func (c *NomadClient) RestartAllocation(ctx context.Context, allocID string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/client/allocation/"+allocID+"/restart", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling nomad: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nomad returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// WaitForEvaluation polls the given evaluation until it reaches a
+// terminal status or ctx is cancelled.
+func (c *NomadClient) WaitForEvaluation(ctx context.Context, evalID string) (*NomadEvaluation, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		eval, err := c.getEvaluation(ctx, evalID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch eval.Status {
+		case "complete", "failed", "cancelled":
+			return eval, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *NomadClient) getEvaluation(ctx context.Context, evalID string) (*NomadEvaluation, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/evaluation/"+evalID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling nomad: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nomad returned %s", resp.Status)
+	}
+
+	var eval NomadEvaluation
+	if err := json.NewDecoder(resp.Body).Decode(&eval); err != nil {
+		return nil, fmt.Errorf("decoding nomad response: %w", err)
+	}
+
+	return &eval, nil
+}
+
+// GetJob fetches a job's current status from Nomad.
+func (c *NomadClient) GetJob(ctx context.Context, job string) (*NomadJob, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/job/"+job, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling nomad: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nomad returned %s", resp.Status)
+	}
+
+	var nomadJob NomadJob
+	if err := json.NewDecoder(resp.Body).Decode(&nomadJob); err != nil {
+		return nil, fmt.Errorf("decoding nomad response: %w", err)
+	}
+
+	return &nomadJob, nil
+}
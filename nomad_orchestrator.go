@@ -0,0 +1,81 @@
+package main
+
+import "context"
+
+// NomadOrchestrator adapts NomadClient to the Orchestrator interface.
+type NomadOrchestrator struct {
+	client *NomadClient
+}
+
+func (o *NomadOrchestrator) Restart(ctx context.Context, job string) (RestartResult, error) {
+	def, err := o.client.getJobDefinition(ctx, job)
+	if err != nil {
+		return RestartResult{}, err
+	}
+
+	if def["Periodic"] != nil {
+		return o.restartPeriodic(ctx, job)
+	}
+
+	return o.restartAllocations(ctx, job)
+}
+
+// restartPeriodic submits the restart via periodic/force, which only
+// Nomad accepts for periodic jobs, then waits for the resulting
+// evaluation to reach a terminal status.
+func (o *NomadOrchestrator) restartPeriodic(ctx context.Context, job string) (RestartResult, error) {
+	evalID, err := o.client.ForceRestart(ctx, job)
+	if err != nil {
+		return RestartResult{}, err
+	}
+
+	eval, err := o.client.WaitForEvaluation(ctx, evalID)
+	if err != nil {
+		return RestartResult{}, err
+	}
+
+	result := RestartResult{JobName: job, Status: eval.Status}
+	for alloc := range eval.FailedTGAllocs {
+		result.Failures = append(result.Failures, alloc)
+	}
+
+	return result, nil
+}
+
+// restartAllocations restarts every running allocation of a service
+// job in place, same as `nomad job restart` does for a non-periodic
+// job.
+func (o *NomadOrchestrator) restartAllocations(ctx context.Context, job string) (RestartResult, error) {
+	allocs, err := o.client.ListAllocations(ctx, job)
+	if err != nil {
+		return RestartResult{}, err
+	}
+
+	result := RestartResult{JobName: job, Status: "restarted"}
+	restarted := 0
+	for _, alloc := range allocs {
+		if alloc.ClientStatus != "running" {
+			continue
+		}
+		if err := o.client.RestartAllocation(ctx, alloc.ID); err != nil {
+			result.Failures = append(result.Failures, alloc.ID)
+			continue
+		}
+		restarted++
+	}
+
+	if restarted == 0 && len(allocs) > 0 {
+		result.Status = "no-op"
+	}
+
+	return result, nil
+}
+
+func (o *NomadOrchestrator) Status(ctx context.Context, job string) (JobStatus, error) {
+	nomadJob, err := o.client.GetJob(ctx, job)
+	if err != nil {
+		return JobStatus{}, err
+	}
+
+	return JobStatus{JobName: job, Status: nomadJob.Status}, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// RestartResult is returned by an Orchestrator after a restart has
+// been submitted and, where the backend supports it, confirmed.
+type RestartResult struct {
+	JobName  string   `json:"job_name"`
+	Status   string   `json:"status"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// JobStatus is a backend-agnostic summary of a managed job's current
+// state.
+type JobStatus struct {
+	JobName string `json:"job_name"`
+	Status  string `json:"status"`
+}
+
+// Orchestrator is implemented by each supported backend so the HTTP
+// handlers can restart and query jobs without knowing whether they
+// live on Nomad, Docker, systemd, or Kubernetes.
+type Orchestrator interface {
+	Restart(ctx context.Context, job string) (RestartResult, error)
+	Status(ctx context.Context, job string) (JobStatus, error)
+}
+
+// newOrchestrator selects and builds the Orchestrator backend named by
+// cfg.OrchestratorBackend.
+func newOrchestrator(cfg *Config) (Orchestrator, error) {
+	switch cfg.OrchestratorBackend {
+	case "nomad", "":
+		client, err := newNomadClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &NomadOrchestrator{client: client}, nil
+	case "docker":
+		return newDockerOrchestrator()
+	case "systemd":
+		return &SystemdOrchestrator{}, nil
+	case "kubernetes":
+		return newKubernetesOrchestrator(cfg.KubernetesNamespace)
+	default:
+		return nil, fmt.Errorf("unknown orchestrator backend %q", cfg.OrchestratorBackend)
+	}
+}
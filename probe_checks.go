@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// runProbeCheck dispatches to the implementation for probe.Kind.
+func runProbeCheck(ctx context.Context, probe ProbeConfig) ProbeResult {
+	switch ProbeKind(probe.Kind) {
+	case ProbeKindHTTP:
+		return runHTTPCheck(ctx, probe)
+	case ProbeKindTCP:
+		return runTCPCheck(ctx, probe)
+	case ProbeKindDNS:
+		return runDNSCheck(ctx, probe)
+	case ProbeKindScripted:
+		return runScriptedCheck(ctx, probe)
+	default:
+		return ProbeResult{Time: time.Now(), Error: fmt.Sprintf("unknown probe kind %q", probe.Kind)}
+	}
+}
+
+// runHTTPCheck fetches probe.Target and checks status, body and TLS
+// expiry, recording per-phase timings via httptrace.
+func runHTTPCheck(ctx context.Context, probe ProbeConfig) ProbeResult {
+	start := time.Now()
+	var phases ProbePhaseDurations
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Target, nil)
+	if err != nil {
+		return ProbeResult{Time: start, Error: err.Error()}
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), httpTraceFor(&phases, start)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		phases.Total = time.Since(start)
+		return ProbeResult{Time: start, Error: err.Error(), Phases: phases}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	phases.Total = time.Since(start)
+
+	if probe.ExpectStatus != 0 && resp.StatusCode != probe.ExpectStatus {
+		return ProbeResult{Time: start, Error: fmt.Sprintf("unexpected status %d", resp.StatusCode), Phases: phases}
+	}
+
+	if probe.BodyRegex != nil && !probe.BodyRegex.Match(body) {
+		return ProbeResult{Time: start, Error: "body did not match expected pattern", Phases: phases}
+	}
+
+	if probe.TLSExpires > 0 && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiry := resp.TLS.PeerCertificates[0].NotAfter
+		if time.Until(expiry) < probe.TLSExpires {
+			return ProbeResult{Time: start, Error: fmt.Sprintf("certificate expires %s", expiry.Format(time.RFC3339)), Phases: phases}
+		}
+	}
+
+	return ProbeResult{Time: start, Success: true, Phases: phases}
+}
+
+func httpTraceFor(phases *ProbePhaseDurations, start time.Time) *httptrace.ClientTrace {
+	var dnsStart, connectStart, tlsStart time.Time
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { phases.DNS = time.Since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { phases.Connect = time.Since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { phases.TLS = time.Since(tlsStart) },
+		GotFirstResponseByte: func() { phases.FirstByte = time.Since(start) },
+	}
+}
+
+// runTCPCheck dials probe.Target and reports whether the connection
+// succeeded.
+func runTCPCheck(ctx context.Context, probe ProbeConfig) ProbeResult {
+	start := time.Now()
+
+	connectStart := time.Now()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", probe.Target)
+	phases := ProbePhaseDurations{Connect: time.Since(connectStart)}
+	phases.Total = time.Since(start)
+
+	if err != nil {
+		return ProbeResult{Time: start, Error: err.Error(), Phases: phases}
+	}
+	conn.Close()
+
+	return ProbeResult{Time: start, Success: true, Phases: phases}
+}
+
+// runDNSCheck resolves probe.Target and reports whether at least one
+// address came back.
+func runDNSCheck(ctx context.Context, probe ProbeConfig) ProbeResult {
+	start := time.Now()
+
+	dnsStart := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, probe.Target)
+	phases := ProbePhaseDurations{DNS: time.Since(dnsStart)}
+	phases.Total = time.Since(start)
+
+	if err != nil {
+		return ProbeResult{Time: start, Error: err.Error(), Phases: phases}
+	}
+	if len(addrs) == 0 {
+		return ProbeResult{Time: start, Error: "no addresses returned", Phases: phases}
+	}
+
+	return ProbeResult{Time: start, Success: true, Phases: phases}
+}
+
+// runScriptedCheck fetches probe.Target and hands the response to a
+// small JS snippet, which must evaluate to a truthy value for the
+// probe to pass. A goroutine interrupts the VM once ctx is done, so a
+// slow or infinite script is bounded by the same timeout as the rest
+// of the probe instead of wedging its goroutine forever.
+func runScriptedCheck(ctx context.Context, probe ProbeConfig) ProbeResult {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Target, nil)
+	if err != nil {
+		return ProbeResult{Time: start, Error: err.Error()}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ProbeResult{Time: start, Error: err.Error(), Phases: ProbePhaseDurations{Total: time.Since(start)}}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	phases := ProbePhaseDurations{Total: time.Since(start)}
+
+	vm := goja.New()
+	vm.Set("response", map[string]interface{}{
+		"status": resp.StatusCode,
+		"body":   string(body),
+	})
+
+	scriptDone := make(chan struct{})
+	defer close(scriptDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			vm.Interrupt("probe timed out")
+		case <-scriptDone:
+		}
+	}()
+
+	value, err := vm.RunString(probe.Script)
+	if err != nil {
+		return ProbeResult{Time: start, Error: fmt.Sprintf("script error: %v", err), Phases: phases}
+	}
+	if !value.ToBoolean() {
+		return ProbeResult{Time: start, Error: "script returned false", Phases: phases}
+	}
+
+	return ProbeResult{Time: start, Success: true, Phases: phases}
+}
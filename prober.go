@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// probeRingBufferSize is the number of past results kept per probe for
+// the GET /probes/{name} timeline.
+const probeRingBufferSize = 100
+
+var probeDurationSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name: "probe_duration_seconds",
+		Help: "Duration of a synthetic probe, broken down by phase",
+	},
+	[]string{"probe", "phase"},
+)
+
+// ProbePhaseDurations breaks a single probe run down into the phases a
+// synthetic monitor would graph.
+type ProbePhaseDurations struct {
+	DNS       time.Duration `json:"dns"`
+	Connect   time.Duration `json:"connect"`
+	TLS       time.Duration `json:"tls"`
+	FirstByte time.Duration `json:"first_byte"`
+	Total     time.Duration `json:"total"`
+}
+
+// ProbeResult is a single run of a probe.
+type ProbeResult struct {
+	Time    time.Time           `json:"time"`
+	Success bool                `json:"success"`
+	Error   string              `json:"error,omitempty"`
+	Phases  ProbePhaseDurations `json:"phases"`
+}
+
+type probeRingBuffer struct {
+	mu      sync.Mutex
+	results []ProbeResult
+}
+
+func (b *probeRingBuffer) Add(result ProbeResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.results = append(b.results, result)
+	if len(b.results) > probeRingBufferSize {
+		b.results = b.results[len(b.results)-probeRingBufferSize:]
+	}
+}
+
+func (b *probeRingBuffer) Snapshot() []ProbeResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]ProbeResult, len(b.results))
+	copy(out, b.results)
+	return out
+}
+
+func (b *probeRingBuffer) Last() (ProbeResult, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.results) == 0 {
+		return ProbeResult{}, false
+	}
+	return b.results[len(b.results)-1], true
+}
+
+// Prober runs each configured probe on its own interval in the
+// background and keeps a ring buffer of recent results per probe.
+type Prober struct {
+	probes  map[string]ProbeConfig
+	results map[string]*probeRingBuffer
+}
+
+func newProber(cfg *ProbesConfig) *Prober {
+	p := &Prober{
+		probes:  make(map[string]ProbeConfig),
+		results: make(map[string]*probeRingBuffer),
+	}
+
+	for _, probe := range cfg.Probes {
+		p.probes[probe.Name] = probe
+		p.results[probe.Name] = &probeRingBuffer{}
+	}
+
+	return p
+}
+
+// Start launches one goroutine per configured probe; each runs until
+// ctx is cancelled.
+func (p *Prober) Start(ctx context.Context) {
+	for name, probe := range p.probes {
+		go p.run(ctx, name, probe)
+	}
+}
+
+func (p *Prober) run(ctx context.Context, name string, probe ProbeConfig) {
+	p.execute(ctx, name, probe)
+
+	ticker := time.NewTicker(probe.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.execute(ctx, name, probe)
+		}
+	}
+}
+
+func (p *Prober) execute(ctx context.Context, name string, probe ProbeConfig) {
+	checkCtx, cancel := context.WithTimeout(ctx, probe.Timeout)
+	defer cancel()
+
+	result := runProbeCheck(checkCtx, probe)
+	p.results[name].Add(result)
+	observeProbePhases(name, result.Phases)
+}
+
+// Summary returns the most recent result for every configured probe.
+func (p *Prober) Summary() map[string]ProbeResult {
+	summary := make(map[string]ProbeResult, len(p.results))
+	for name, buf := range p.results {
+		if last, ok := buf.Last(); ok {
+			summary[name] = last
+		}
+	}
+	return summary
+}
+
+// Timeline returns the full ring buffer recorded for a single probe.
+func (p *Prober) Timeline(name string) ([]ProbeResult, bool) {
+	buf, ok := p.results[name]
+	if !ok {
+		return nil, false
+	}
+	return buf.Snapshot(), true
+}
+
+func observeProbePhases(name string, phases ProbePhaseDurations) {
+	if phases.DNS > 0 {
+		probeDurationSeconds.WithLabelValues(name, "dns").Observe(phases.DNS.Seconds())
+	}
+	if phases.Connect > 0 {
+		probeDurationSeconds.WithLabelValues(name, "connect").Observe(phases.Connect.Seconds())
+	}
+	if phases.TLS > 0 {
+		probeDurationSeconds.WithLabelValues(name, "tls").Observe(phases.TLS.Seconds())
+	}
+	if phases.FirstByte > 0 {
+		probeDurationSeconds.WithLabelValues(name, "firstbyte").Observe(phases.FirstByte.Seconds())
+	}
+	probeDurationSeconds.WithLabelValues(name, "total").Observe(phases.Total.Seconds())
+}
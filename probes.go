@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProbeKind enumerates the supported synthetic check types.
+type ProbeKind string
+
+const (
+	ProbeKindHTTP     ProbeKind = "http"
+	ProbeKindTCP      ProbeKind = "tcp"
+	ProbeKindDNS      ProbeKind = "dns"
+	ProbeKindScripted ProbeKind = "scripted"
+)
+
+// ProbeConfig describes one synthetic check as declared in probes.yaml.
+type ProbeConfig struct {
+	Name            string `yaml:"name"`
+	Kind            string `yaml:"kind"`
+	Target          string `yaml:"target"`
+	IntervalRaw     string `yaml:"interval"`
+	TimeoutRaw      string `yaml:"timeout"`
+	ExpectStatus    int    `yaml:"expect_status"`
+	ExpectBodyRegex string `yaml:"expect_body_regex"`
+	TLSExpiryWithin string `yaml:"tls_expiry_within"`
+	Script          string `yaml:"script"`
+
+	Interval   time.Duration  `yaml:"-"`
+	Timeout    time.Duration  `yaml:"-"`
+	BodyRegex  *regexp.Regexp `yaml:"-"`
+	TLSExpires time.Duration  `yaml:"-"`
+}
+
+// ProbesConfig is the top-level shape of probes.yaml.
+type ProbesConfig struct {
+	Probes []ProbeConfig `yaml:"probes"`
+}
+
+// loadProbesConfig reads and validates probes.yaml. A missing file is
+// not an error — it just means no synthetic probes are configured.
+func loadProbesConfig(path string) (*ProbesConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ProbesConfig{}, nil
+		}
+		return nil, fmt.Errorf("reading probes config %s: %w", path, err)
+	}
+
+	var cfg ProbesConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing probes config %s: %w", path, err)
+	}
+
+	for i := range cfg.Probes {
+		probe := &cfg.Probes[i]
+
+		probe.Interval = 30 * time.Second
+		if probe.IntervalRaw != "" {
+			d, err := time.ParseDuration(probe.IntervalRaw)
+			if err != nil {
+				return nil, fmt.Errorf("parsing interval for probe %s: %w", probe.Name, err)
+			}
+			probe.Interval = d
+		}
+
+		probe.Timeout = 5 * time.Second
+		if probe.TimeoutRaw != "" {
+			d, err := time.ParseDuration(probe.TimeoutRaw)
+			if err != nil {
+				return nil, fmt.Errorf("parsing timeout for probe %s: %w", probe.Name, err)
+			}
+			probe.Timeout = d
+		}
+
+		if probe.ExpectBodyRegex != "" {
+			re, err := regexp.Compile(probe.ExpectBodyRegex)
+			if err != nil {
+				return nil, fmt.Errorf("parsing expect_body_regex for probe %s: %w", probe.Name, err)
+			}
+			probe.BodyRegex = re
+		}
+
+		if probe.TLSExpiryWithin != "" {
+			d, err := time.ParseDuration(probe.TLSExpiryWithin)
+			if err != nil {
+				return nil, fmt.Errorf("parsing tls_expiry_within for probe %s: %w", probe.Name, err)
+			}
+			probe.TLSExpires = d
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+var rateLimitDroppedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "rate_limit_dropped_total",
+		Help: "Total number of requests rejected by the rate limiter",
+	},
+	[]string{"endpoint", "ip_class"},
+)
+
+// EndpointQuota is the allow rate and burst applied to one route.
+type EndpointQuota struct {
+	Rate  rate.Limit
+	Burst int
+}
+
+// endpointQuotas holds the per-route limits named in the request. It's
+// populated from config.EndpointQuotas at startup; see
+// parseEndpointQuotas for the format.
+var endpointQuotas map[string]EndpointQuota
+
+// parseEndpointQuotas parses a comma-separated "route=interval:burst"
+// list (e.g. "/restart=1m:1,/healthz=1s:60,default=1s:5") into the
+// per-route quota map consulted by quotaFor. interval is how often the
+// bucket refills by one token, so "1m:1" allows one request per minute
+// with no burst. /restart is expensive and rarely legitimate in bulk,
+// while /healthz is expected to be polled often. Anything not listed
+// falls back to "default".
+func parseEndpointQuotas(raw string) map[string]EndpointQuota {
+	quotas := map[string]EndpointQuota{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			logger.Warn("Ignoring malformed endpoint quota entry", "input", entry)
+			continue
+		}
+		interval, burstStr, ok := strings.Cut(spec, ":")
+		if !ok {
+			logger.Warn("Ignoring malformed endpoint quota entry", "input", entry)
+			continue
+		}
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			logger.Warn("Ignoring invalid endpoint quota interval", "input", entry, "error", err)
+			continue
+		}
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil {
+			logger.Warn("Ignoring invalid endpoint quota burst", "input", entry, "error", err)
+			continue
+		}
+		quotas[route] = EndpointQuota{Rate: rate.Every(d), Burst: burst}
+	}
+	return quotas
+}
+
+func quotaFor(endpoint string) EndpointQuota {
+	if q, ok := endpointQuotas[endpoint]; ok {
+		return q
+	}
+	return endpointQuotas["default"]
+}
+
+// RateLimitBackend is implemented by each supported limiter store — an
+// in-memory LRU for single-node deployments, or Redis for multi-replica
+// ones that need to share state.
+type RateLimitBackend interface {
+	// Allow reports whether the caller identified by key may proceed
+	// under quota, and if not, how long it should wait before retrying.
+	Allow(ctx context.Context, key string, quota EndpointQuota) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// newRateLimitBackend selects and builds the RateLimitBackend named by
+// cfg.RateLimitBackend.
+func newRateLimitBackend(cfg *Config) (RateLimitBackend, error) {
+	switch cfg.RateLimitBackend {
+	case "memory", "":
+		return newMemoryRateLimitBackend(cfg.RateLimitLRUSize)
+	case "redis":
+		if cfg.RateLimitRedisAddr == "" {
+			return nil, fmt.Errorf("RATE_LIMIT_REDIS_ADDR is required for the redis rate limit backend")
+		}
+		return newRedisRateLimitBackend(cfg.RateLimitRedisAddr), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", cfg.RateLimitBackend)
+	}
+}
+
+// parseTrustedProxies parses a comma-separated list of CIDRs naming the
+// proxies allowed to set X-Forwarded-For.
+func parseTrustedProxies(raw string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			logger.Warn("Ignoring invalid trusted proxy CIDR", "input", entry, "error", err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// clientIP resolves the caller's IP, honoring X-Forwarded-For only when
+// the immediate peer is within a trusted proxy range — otherwise a
+// caller could simply spoof the header to dodge its own limit. Once
+// trusted, we walk the header right-to-left and take the first entry
+// that isn't itself a trusted proxy: each trusted hop appends the
+// address it saw, so the rightmost untrusted entry is the one no proxy
+// vouched for, while the leftmost entry is whatever the client wrote and
+// so is never safe to trust.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer := net.ParseIP(host)
+	if peer == nil || !ipInAny(peer, trustedProxies) {
+		return host
+	}
+
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded == "" {
+		return host
+	}
+
+	entries := strings.Split(forwarded, ",")
+	for i := len(entries) - 1; i >= 0; i-- {
+		candidate := strings.TrimSpace(entries[i])
+		ip := net.ParseIP(candidate)
+		if ip == nil || ipInAny(ip, trustedProxies) {
+			continue
+		}
+		return candidate
+	}
+
+	return host
+}
+
+func ipInAny(ip net.IP, nets []*net.IPNet) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipClass buckets an IP for the rate_limit_dropped_total metric without
+// leaking raw addresses into label values.
+func ipClass(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "unknown"
+	}
+	if parsed.IsLoopback() || parsed.IsPrivate() {
+		return "internal"
+	}
+	return "external"
+}
+
+// keyedRateLimitMiddleware rate-limits requests per (endpoint, client
+// IP) against rateLimitBackend, applying endpoint's quota and setting
+// Retry-After on rejection.
+func keyedRateLimitMiddleware(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	quota := quotaFor(endpoint)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, trustedProxyNets)
+		key := endpoint + ":" + ip
+
+		allowed, retryAfter, err := rateLimitBackend.Allow(r.Context(), key, quota)
+		if err != nil {
+			logger.Error("Rate limit backend error, allowing request", "endpoint", endpoint, "error", err)
+			next(w, r)
+			return
+		}
+
+		if !allowed {
+			class := ipClass(ip)
+			rateLimitDroppedTotal.WithLabelValues(endpoint, class).Inc()
+			logger.Warn("Rate limit exceeded", "endpoint", endpoint, "remote_addr", r.RemoteAddr, "ip_class", class)
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(retryAfter.Seconds()+1)))
+			sendErrorResponse(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript implements a sliding-window counter over a Redis
+// sorted set: each call drops entries older than the window, then
+// either admits the request (recording it) or reports how much longer
+// the caller must wait. Running it as a single script keeps the
+// read-modify-write atomic across replicas.
+const slidingWindowScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call("ZREMRANGEBYSCORE", key, 0, now - window)
+
+local count = redis.call("ZCARD", key)
+if count >= limit then
+	local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+	local retryAfter = window
+	if oldest[2] then
+		retryAfter = window - (now - tonumber(oldest[2]))
+	end
+	return retryAfter
+end
+
+redis.call("ZADD", key, now, member)
+redis.call("PEXPIRE", key, window)
+return 0
+`
+
+// redisRateLimitBackend implements a sliding-window limiter in Redis so
+// multiple replicas share the same per-endpoint, per-IP quota.
+type redisRateLimitBackend struct {
+	client *redis.Client
+	script *redis.Script
+}
+
+func newRedisRateLimitBackend(addr string) *redisRateLimitBackend {
+	return &redisRateLimitBackend{
+		client: redis.NewClient(&redis.Options{Addr: addr}),
+		script: redis.NewScript(slidingWindowScript),
+	}
+}
+
+func (b *redisRateLimitBackend) Allow(ctx context.Context, key string, quota EndpointQuota) (bool, time.Duration, error) {
+	windowMillis := int64(time.Second / time.Millisecond)
+	if quota.Rate > 0 {
+		windowMillis = int64(float64(quota.Burst) / float64(quota.Rate) * 1000)
+	}
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, rand.Int63())
+
+	retryAfterMillis, err := b.script.Run(ctx, b.client, []string{key}, now, windowMillis, quota.Burst, member).Int64()
+	if err != nil {
+		return false, 0, fmt.Errorf("running rate limit script: %w", err)
+	}
+
+	if retryAfterMillis == 0 {
+		return true, 0, nil
+	}
+	return false, time.Duration(retryAfterMillis) * time.Millisecond, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// drainMu guards draining and inFlightRestarts.Add so a restart can
+// never register itself after waitForDrain has already observed the
+// WaitGroup reach zero — checking draining and adding to the group has
+// to happen as one atomic step, not two separate ones.
+var drainMu sync.Mutex
+
+// draining is set once the server begins graceful shutdown; new
+// restart requests are refused while ones already in flight are given
+// up to DrainTimeout to finish. Always accessed under drainMu.
+var draining bool
+
+// inFlightRestarts tracks restart requests currently being serviced so
+// shutdown can wait for them before closing the server. Only Add is
+// guarded by drainMu; Wait and Done don't need it.
+var inFlightRestarts sync.WaitGroup
+
+// ShutdownResponse is returned to callers whose restart request is
+// refused because the server is draining.
+type ShutdownResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// beginRestart reports whether a new restart may proceed, registering
+// it with inFlightRestarts if so. When ok is true, the caller must
+// invoke done once the restart completes.
+func beginRestart() (done func(), ok bool) {
+	drainMu.Lock()
+	defer drainMu.Unlock()
+
+	if draining {
+		return nil, false
+	}
+	inFlightRestarts.Add(1)
+	return inFlightRestarts.Done, true
+}
+
+// beginDraining marks the server as draining so beginRestart refuses
+// new restarts. It shares drainMu with beginRestart so draining can't
+// be set between a restart's draining check and its Add.
+func beginDraining() {
+	drainMu.Lock()
+	draining = true
+	drainMu.Unlock()
+}
+
+// clearDraining un-drains the server; only used by tests to restore
+// state between cases since draining is never cleared in production.
+func clearDraining() {
+	drainMu.Lock()
+	draining = false
+	drainMu.Unlock()
+}
+
+func sendDrainingResponse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(ShutdownResponse{
+		Error:   http.StatusText(http.StatusServiceUnavailable),
+		Message: "shutdown in progress, not accepting new restart requests",
+	})
+}
+
+// waitForDrain blocks until every in-flight restart finishes or
+// timeout elapses, whichever comes first.
+func waitForDrain(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		inFlightRestarts.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("All in-flight restarts drained")
+	case <-time.After(timeout):
+		logger.Warn("Drain timeout elapsed with restarts still in flight")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// SystemdOrchestrator restarts a unit on the local host via dbus, for
+// deployments that run Jellyfin as a plain systemd service.
+type SystemdOrchestrator struct{}
+
+func (o *SystemdOrchestrator) Restart(ctx context.Context, job string) (RestartResult, error) {
+	conn, err := dbus.NewSystemConnectionContext(ctx)
+	if err != nil {
+		return RestartResult{}, fmt.Errorf("connecting to systemd: %w", err)
+	}
+	defer conn.Close()
+
+	resultCh := make(chan string, 1)
+	if _, err := conn.RestartUnitContext(ctx, job, "replace", resultCh); err != nil {
+		return RestartResult{}, fmt.Errorf("restarting unit %s: %w", job, err)
+	}
+
+	select {
+	case status := <-resultCh:
+		return RestartResult{JobName: job, Status: status}, nil
+	case <-ctx.Done():
+		return RestartResult{}, ctx.Err()
+	}
+}
+
+func (o *SystemdOrchestrator) Status(ctx context.Context, job string) (JobStatus, error) {
+	conn, err := dbus.NewSystemConnectionContext(ctx)
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("connecting to systemd: %w", err)
+	}
+	defer conn.Close()
+
+	props, err := conn.GetUnitPropertiesContext(ctx, job)
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("getting unit %s: %w", job, err)
+	}
+
+	return JobStatus{JobName: job, Status: fmt.Sprintf("%v", props["ActiveState"])}, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// routeTimeouts caps how long each route's handler may run before the
+// caller gets a 503, independent of the rate limit quota it's also
+// subject to. It's populated from config.RouteTimeouts at startup; see
+// parseRouteTimeouts for the format.
+var routeTimeouts map[string]time.Duration
+
+// parseRouteTimeouts parses a comma-separated "route=duration" list
+// (e.g. "/restart=90s,/healthz=12s,default=30s") into the per-route
+// timeout map consulted by timeoutFor. /restart is slow because it
+// waits on the orchestrator; /healthz is expected back fast since it
+// only reads the prober's cached results.
+func parseRouteTimeouts(raw string) map[string]time.Duration {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			logger.Warn("Ignoring malformed route timeout entry", "input", entry)
+			continue
+		}
+		d, err := time.ParseDuration(spec)
+		if err != nil {
+			logger.Warn("Ignoring invalid route timeout duration", "input", entry, "error", err)
+			continue
+		}
+		timeouts[route] = d
+	}
+	return timeouts
+}
+
+func timeoutFor(endpoint string) time.Duration {
+	if d, ok := routeTimeouts[endpoint]; ok {
+		return d
+	}
+	return routeTimeouts["default"]
+}
+
+// withTimeout bounds next to its configured route budget, responding
+// 503 if the handler hasn't finished once the budget elapses.
+func withTimeout(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	duration := timeoutFor(endpoint)
+	message := fmt.Sprintf("request to %s timed out after %s", endpoint, duration)
+	return http.TimeoutHandler(next, duration, message).ServeHTTP
+}